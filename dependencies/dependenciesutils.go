@@ -1,6 +1,7 @@
 package dependencies
 
 import (
+	"archive/zip"
 	"bytes"
 	"fmt"
 	"github.com/jfrog/gocmd/utils/cache"
@@ -10,84 +11,179 @@ import (
 	"github.com/jfrog/jfrog-client-go/artifactory/buildinfo"
 	"github.com/jfrog/jfrog-client-go/httpclient"
 	"github.com/jfrog/jfrog-client-go/utils/errorutils"
-	multifilereader "github.com/jfrog/jfrog-client-go/utils/io"
 	"github.com/jfrog/jfrog-client-go/utils/io/fileutils"
 	"github.com/jfrog/jfrog-client-go/utils/io/fileutils/checksum"
 	"github.com/jfrog/jfrog-client-go/utils/log"
 	"github.com/pkg/errors"
+	"golang.org/x/mod/modfile"
+	"golang.org/x/sync/errgroup"
+	"io"
+	"io/fs"
 	"io/ioutil"
 	"net/http"
 	"os"
 	"path/filepath"
+	"runtime"
+	"strconv"
 	"strings"
-	"unicode"
+	"sync"
 )
 
-const (
-	FailedToRetrieve          = "Failed to retrieve"
-	FromBothArtifactoryAndVcs = "from both Artifactory and VCS"
-)
+const FailedToRetrieve = "Failed to retrieve"
 
-// Collects the dependencies of the project
-func CollectProjectDependencies(targetRepo, rootProjectDir string, cache *cache.DependenciesCache, auth auth.ArtifactoryDetails) (map[string]bool, error) {
+// Collects the dependencies of the project.
+// verifyMode controls whether the downloaded modules are checked against go.sum / GOSUMDB
+// (see VerifyMode); pinFilePath may point to a user-supplied go.sum-style file of pinned
+// hashes and can be left empty. The returned []LocalReplace holds any go.mod "replace"
+// directives pointing at a local filesystem path; it is up to the caller to decide whether to
+// treat those as workspace dependencies or skip them, since they are not resolved through
+// Artifactory.
+func CollectProjectDependencies(targetRepo, rootProjectDir string, cache *cache.DependenciesCache, auth auth.ArtifactoryDetails, verifyMode VerifyMode, pinFilePath string) (map[string]bool, []LocalReplace, error) {
 	dependenciesMap, err := getDependenciesGraphWithFallback(targetRepo, auth)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-	replaceDependencies, err := getReplaceDependencies()
+	versionReplaces, localReplaces, err := getReplaceDependencies()
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	// Merge replaceDependencies with dependenciesToPublish
-	mergeReplaceDependenciesWithGraphDependencies(replaceDependencies, dependenciesMap)
+	// Merge versionReplaces with dependenciesToPublish
+	mergeReplaceDependenciesWithGraphDependencies(versionReplaces, dependenciesMap)
 	sumFileContent, sumFileStat, err := cmd.GetSumContentAndRemove(rootProjectDir)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	if len(sumFileContent) > 0 && sumFileStat != nil {
 		defer cmd.RestoreSumFile(rootProjectDir, sumFileContent, sumFileStat)
 	}
-	projectDependencies, err := downloadDependencies(targetRepo, cache, dependenciesMap, auth)
+	goSumHashes := parseSumLinesFromContent(sumFileContent)
+	var pinHashes map[string]moduleHashes
+	if pinFilePath != "" {
+		pinHashes, err = parseSumLines(pinFilePath)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	projectDependencies, err := downloadDependencies(targetRepo, cache, dependenciesMap, auth, verifyMode, pinHashes, goSumHashes)
 	if err != nil {
-		return projectDependencies, err
+		return projectDependencies, localReplaces, err
 	}
-	return projectDependencies, nil
+	return projectDependencies, localReplaces, nil
 }
 
-func downloadDependencies(targetRepo string, cache *cache.DependenciesCache, depSlice map[string]bool, auth auth.ArtifactoryDetails) (map[string]bool, error) {
+// downloadParallelismEnvVar overrides the number of modules downloaded concurrently.
+// Defaults to runtime.NumCPU() when unset or invalid.
+const downloadParallelismEnvVar = "GOCMD_DOWNLOAD_PARALLELISM"
+
+// downloadInvocationMutex guards Configure (which mutates the process-wide GOPROXY environment
+// variable) together with the Download call it configures for, so one worker's GOPROXY can
+// never be overwritten by another's before its "go mod download" invocation runs.
+var downloadInvocationMutex sync.Mutex
+
+// downloadDependencies resolves and downloads every module in depSlice, using a bounded pool
+// of workers (see downloadParallelismEnvVar) to overlap the network-bound HEAD probes across
+// modules. The "go mod download" calls themselves still run one at a time, serialized by
+// downloadInvocationMutex, since they go through the process-wide GOPROXY environment variable;
+// fully overlapping them too would mean threading GOPROXY through cmd.DownloadDependency's
+// exec.Cmd.Env instead.
+func downloadDependencies(targetRepo string, cache *cache.DependenciesCache, depSlice map[string]bool, auth auth.ArtifactoryDetails, verifyMode VerifyMode, pinHashes map[string]moduleHashes, goSumHashes map[string]moduleHashes) (map[string]bool, error) {
 	client, err := httpclient.ClientBuilder().Build()
 	if err != nil {
 		return nil, err
 	}
+	cachePath, err := getModCachePath()
+	if err != nil {
+		return nil, err
+	}
+
+	resolvers := buildResolverChain(targetRepo, auth)
 	cacheDependenciesMap := cache.GetMap()
 	dependenciesMap := map[string]bool{}
+	var mapMutex sync.Mutex
+
+	var errGroup errgroup.Group
+	semaphore := make(chan struct{}, getDownloadParallelism())
+
 	for module := range depSlice {
-		nameAndVersion := strings.Split(module, "@")
-		resp, err := performHeadRequest(auth, client, targetRepo, nameAndVersion[0], nameAndVersion[1])
-		if err != nil {
-			return dependenciesMap, err
-		}
+		module := module
+		semaphore <- struct{}{}
+		errGroup.Go(func() error {
+			defer func() { <-semaphore }()
+			return downloadSingleDependency(module, resolvers, client, cachePath, verifyMode, pinHashes, goSumHashes, cacheDependenciesMap, dependenciesMap, &mapMutex)
+		})
+	}
 
-		if resp.StatusCode == 200 {
-			cacheDependenciesMap[getDependencyName(nameAndVersion[0])+":"+nameAndVersion[1]] = true
-			err = downloadDependency(true, module, targetRepo, auth)
-			dependenciesMap[module] = true
-		} else if resp.StatusCode == 404 {
-			cacheDependenciesMap[getDependencyName(nameAndVersion[0])+":"+nameAndVersion[1]] = false
-			err = downloadDependency(false, module, "", nil)
-			dependenciesMap[module] = false
-		}
+	if err := errGroup.Wait(); err != nil {
+		return dependenciesMap, err
+	}
+	return dependenciesMap, nil
+}
 
-		if err != nil {
-			return dependenciesMap, err
+// downloadSingleDependency resolves and downloads a single "module@version" entry against the
+// given resolver chain. It is safe to call concurrently for different modules;
+// cacheDependenciesMap and dependenciesMap are guarded by mapMutex since they are shared
+// across workers.
+func downloadSingleDependency(module string, resolvers []Resolver, client *httpclient.HttpClient, cachePath string, verifyMode VerifyMode, pinHashes map[string]moduleHashes, goSumHashes map[string]moduleHashes, cacheDependenciesMap, dependenciesMap map[string]bool, mapMutex *sync.Mutex) error {
+	nameAndVersion := strings.Split(module, "@")
+	name, version := nameAndVersion[0], nameAndVersion[1]
+
+	resolver, found, err := resolveModule(resolvers, client, name, version)
+	if err != nil {
+		return err
+	}
+	if !found {
+		log.Debug(fmt.Sprintf("No resolver confirmed %s@%s is available; attempting download from %s anyway.", name, version, resolver.Name()))
+	}
+
+	escapedName, _, err := escapeModuleAndVersion(name, version)
+	if err != nil {
+		return err
+	}
+	_, fromArtifactory := resolver.(*ArtifactoryProxy)
+	mapMutex.Lock()
+	cacheDependenciesMap[escapedName+":"+version] = fromArtifactory
+	mapMutex.Unlock()
+
+	downloadInvocationMutex.Lock()
+	err = resolver.Configure()
+	if err == nil {
+		err = resolver.Download(module)
+	}
+	downloadInvocationMutex.Unlock()
+	if err != nil {
+		return err
+	}
+
+	mapMutex.Lock()
+	dependenciesMap[module] = fromArtifactory
+	mapMutex.Unlock()
+
+	if verifyMode != VerifyOff {
+		targetRepo, resolverAuth := artifactoryDetailsOf(resolver)
+		return verifyModule(verifyMode, name, version, cachePath, goSumHashes, pinHashes, targetRepo, resolverAuth, client)
+	}
+	return nil
+}
+
+// getDownloadParallelism returns the configured module-download concurrency, falling back to
+// runtime.NumCPU() if downloadParallelismEnvVar is unset or not a positive integer.
+func getDownloadParallelism() int {
+	if value := os.Getenv(downloadParallelismEnvVar); value != "" {
+		if parsed, err := strconv.Atoi(value); err == nil && parsed > 0 {
+			return parsed
 		}
+		log.Warn(fmt.Sprintf("Invalid value for %s: '%s'. Falling back to the default parallelism.", downloadParallelismEnvVar, value))
 	}
-	return dependenciesMap, nil
+	return runtime.NumCPU()
 }
 
-func performHeadRequest(auth auth.ArtifactoryDetails, client *httpclient.HttpClient, targetRepo, module, version string) (*http.Response, error) {
-	url := auth.GetUrl() + "api/go/" + targetRepo + "/" + module + "/@v/" + version + ".mod"
+func performHeadRequest(auth auth.ArtifactoryDetails, client *httpclient.HttpClient, targetRepo, moduleName, version string) (*http.Response, error) {
+	escapedName, escapedVersion, err := escapeModuleAndVersion(moduleName, version)
+	if err != nil {
+		return nil, err
+	}
+	url := auth.GetUrl() + "api/go/" + targetRepo + "/" + escapedName + "/@v/" + escapedVersion + ".mod"
 	resp, _, err := client.SendHead(url, auth.CreateHttpClientDetails())
 	if err != nil {
 		return nil, err
@@ -96,60 +192,105 @@ func performHeadRequest(auth auth.ArtifactoryDetails, client *httpclient.HttpCli
 	return resp, nil
 }
 
-// Creating dependency with the mod file in the temp directory
+// Creating dependency with the mod file in the temp directory. The zip is read lazily via
+// archive/zip.OpenReader rather than buffered into a MultiFileReaderAt, so this also works for
+// zips too large to fit in memory (or the address space on 32-bit systems).
 func createDependencyInTemp(zipPath string) (tempDir string, err error) {
 	tempDir, err = fileutils.GetTempDirPath()
 	if err != nil {
 		return "", err
 	}
-	multiReader, err := multifilereader.NewMultiFileReaderAt([]string{zipPath})
+	zipReadCloser, err := zip.OpenReader(zipPath)
 	if err != nil {
 		return "", errorutils.CheckError(err)
 	}
-	err = fileutils.Unzip(multiReader, multiReader.Size(), tempDir)
-	if err != nil {
+	defer zipReadCloser.Close()
+
+	if err := extractZipFS(&zipReadCloser.Reader, tempDir); err != nil {
 		return "", errorutils.CheckError(err)
 	}
 	return tempDir, nil
 }
 
-func replaceExclamationMarkWithUpperCase(moduleName string) string {
-	var str string
-	for i := 0; i < len(moduleName); i++ {
-		if string(moduleName[i]) == "!" {
-			if i < len(moduleName)-1 {
-				r := rune(moduleName[i+1])
-				str += string(unicode.ToUpper(r))
-				i++
-			}
-		} else {
-			str += string(moduleName[i])
+// extractZipFS walks zipReader's fs.FS view and streams every regular file it contains out to
+// destDir, one entry at a time, instead of materializing the whole archive in memory first.
+func extractZipFS(zipReader *zip.Reader, destDir string) error {
+	return fs.WalkDir(zipReader, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
 		}
-	}
-	return str
+		if d.IsDir() {
+			return nil
+		}
+		target := filepath.Join(destDir, filepath.FromSlash(path))
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		}
+		src, err := zipReader.Open(path)
+		if err != nil {
+			return err
+		}
+		defer src.Close()
+
+		dst, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+		if err != nil {
+			return err
+		}
+		defer dst.Close()
+
+		_, err = io.Copy(dst, src)
+		return err
+	})
 }
 
-// Runs the go mod download command. Should set first the environment variable of GoProxy
-func downloadDependency(downloadFromArtifactory bool, fullDependencyName, targetRepo string, auth auth.ArtifactoryDetails) error {
-	var err error
-	if downloadFromArtifactory {
-		log.Debug("Downloading dependency from Artifactory:", fullDependencyName)
-		err = cmd.SetGoProxyEnvVar(auth.GetUrl(), auth.GetUser(), auth.GetPassword(), targetRepo)
-	} else {
-		log.Debug("Downloading dependency from VCS:", fullDependencyName)
-		err = os.Unsetenv(cmd.GOPROXY)
+// ModuleFS opens a module's cached zip (as downloaded by CollectProjectDependencies) as a
+// read-only fs.FS, stripping the "<module>@<version>/" prefix the go command stores module
+// content under so paths match what go/packages expects. Downstream consumers such as license
+// scanners or SBOM generators can walk the result without extracting the module to disk first.
+// The returned fs.FS also implements io.Closer; callers that want deterministic cleanup of the
+// underlying zip file handle should type-assert to it and call Close when done.
+func ModuleFS(cachePath, name, version string) (fs.FS, error) {
+	escapedName, escapedVersion, err := escapeModuleAndVersion(name, version)
+	if err != nil {
+		return nil, err
 	}
-	if errorutils.CheckError(err) != nil {
-		return err
+	zipPath, err := getPackageZipLocation(cachePath, escapedName, escapedVersion)
+	if err != nil {
+		return nil, err
+	}
+	if zipPath == "" {
+		return nil, errorutils.CheckError(errors.New(fmt.Sprintf("no cached zip found for %s@%s under %s", name, version, cachePath)))
 	}
 
-	err = cmd.DownloadDependency(fullDependencyName)
-	return err
+	zipReadCloser, err := zip.OpenReader(zipPath)
+	if err != nil {
+		return nil, errorutils.CheckError(err)
+	}
+	moduleFS, err := fs.Sub(zipReadCloser, name+"@"+version)
+	if err != nil {
+		zipReadCloser.Close()
+		return nil, errorutils.CheckError(err)
+	}
+	return &closableModuleFS{FS: moduleFS, closer: zipReadCloser}, nil
+}
+
+// closableModuleFS pairs an fs.FS view of a module with the underlying zip.ReadCloser, so
+// callers that want deterministic cleanup can type-assert to io.Closer.
+type closableModuleFS struct {
+	fs.FS
+	closer io.Closer
 }
 
+func (m *closableModuleFS) Close() error { return m.closer.Close() }
+
 // Downloads the mod file from Artifactory to the Go cache
 func downloadModFileFromArtifactoryToLocalCache(cachePath, targetRepo, name, version string, auth auth.ArtifactoryDetails, client *httpclient.HttpClient) string {
-	pathToModuleCache := filepath.Join(cachePath, name, "@v")
+	escapedName, escapedVersion, err := escapeModuleAndVersion(name, version)
+	if err != nil {
+		log.Error(fmt.Sprintf("Received an error: %s for %s@%s", err, name, version))
+		return ""
+	}
+	pathToModuleCache := filepath.Join(cachePath, escapedName, "@v")
 	dirExists, err := fileutils.IsDirExists(pathToModuleCache, false)
 	if err != nil {
 		log.Error(fmt.Sprintf("Received an error: %s for %s@%s", err, name, version))
@@ -157,14 +298,14 @@ func downloadModFileFromArtifactoryToLocalCache(cachePath, targetRepo, name, ver
 	}
 
 	if dirExists {
-		url := auth.GetUrl() + "api/go/" + targetRepo + "/" + name + "/@v/" + version + ".mod"
+		url := auth.GetUrl() + "api/go/" + targetRepo + "/" + escapedName + "/@v/" + escapedVersion + ".mod"
 		log.Debug("Downloading mod file from Artifactory:", url)
 		downloadFileDetails := &httpclient.DownloadFileDetails{
-			FileName: version + ".mod",
+			FileName: escapedVersion + ".mod",
 			// Artifactory URL
 			DownloadPath:  url,
 			LocalPath:     pathToModuleCache,
-			LocalFileName: version + ".mod",
+			LocalFileName: escapedVersion + ".mod",
 		}
 		resp, err := client.DownloadFile(downloadFileDetails, "", auth.CreateHttpClientDetails(), 3, false)
 		if err != nil {
@@ -178,36 +319,67 @@ func downloadModFileFromArtifactoryToLocalCache(cachePath, targetRepo, name, ver
 	return ""
 }
 
-func GetRegex() (regExp *RegExp, err error) {
-	emptyRegex, err := cmd.GetRegExp(`^\s*require (?:[\(\w\.@:%_\+-.~#?&]?.+)`)
+// RequireDependency is a single entry of the project's go.mod "require" block.
+type RequireDependency struct {
+	Path     string
+	Version  string
+	Indirect bool
+}
+
+// GetRequireDependencies parses the project's go.mod structurally with
+// golang.org/x/mod/modfile, rather than matching "require" lines with hand-rolled regexes, so
+// block-form "require (...)" stanzas and mid-line "// indirect" comments are all handled
+// correctly.
+func GetRequireDependencies() (requireDependencies []RequireDependency, err error) {
+	rootDir, err := cmd.GetProjectRoot()
 	if err != nil {
-		return
+		return nil, err
 	}
-
-	indirectRegex, err := cmd.GetRegExp(`(// indirect)$`)
+	modFilePath := filepath.Join(rootDir, "go.mod")
+	modFileContent, err := ioutil.ReadFile(modFilePath)
 	if err != nil {
-		return
+		return nil, err
 	}
-
-	generatedBy, err := cmd.GetRegExp(`^(// )`)
+	parsedModFile, err := modfile.Parse(modFilePath, modFileContent, nil)
 	if err != nil {
-		return
+		return nil, errorutils.CheckError(err)
 	}
 
-	regExp = &RegExp{
-		notEmptyModRegex: emptyRegex,
-		indirectRegex:    indirectRegex,
-		generatedBy:      generatedBy,
+	requireDependencies = make([]RequireDependency, 0, len(parsedModFile.Require))
+	for _, require := range parsedModFile.Require {
+		requireDependencies = append(requireDependencies, RequireDependency{
+			Path:     require.Mod.Path,
+			Version:  require.Mod.Version,
+			Indirect: require.Indirect,
+		})
 	}
-	return
+	return requireDependencies, nil
 }
 
-func downloadAndCreateDependency(cachePath, name, version, fullDependencyName, targetRepo string, downloadedFromArtifactory bool, auth auth.ArtifactoryDetails) (*Package, error) {
+func downloadAndCreateDependency(cachePath, name, version, fullDependencyName string, resolver Resolver, verifyMode VerifyMode, pinHashes map[string]moduleHashes, goSumHashes map[string]moduleHashes) (*Package, error) {
 	// Dependency is missing within the cache. Need to download it...
-	err := downloadDependency(downloadedFromArtifactory, fullDependencyName, targetRepo, auth)
+	// Configure and Download are locked together, same as downloadSingleDependency, since
+	// Configure mutates the process-wide GOPROXY environment variable.
+	downloadInvocationMutex.Lock()
+	err := resolver.Configure()
+	if err == nil {
+		err = resolver.Download(fullDependencyName)
+	}
+	downloadInvocationMutex.Unlock()
 	if err != nil {
 		return nil, err
 	}
+	if verifyMode != VerifyOff {
+		client, err := httpclient.ClientBuilder().Build()
+		if err != nil {
+			return nil, err
+		}
+		targetRepo, resolverAuth := artifactoryDetailsOf(resolver)
+		err = verifyModule(verifyMode, name, version, cachePath, goSumHashes, pinHashes, targetRepo, resolverAuth, client)
+		if err != nil {
+			return nil, err
+		}
+	}
 	// Now that this dependency in the cache, get the dependency object
 	dep, err := createDependency(cachePath, name, version)
 	if err != nil {
@@ -222,23 +394,11 @@ func logError(err error) {
 	}
 }
 
-func shouldDownloadFromArtifactory(module, version, targetRepo string, auth auth.ArtifactoryDetails, client *httpclient.HttpClient) (bool, error) {
-	res, err := performHeadRequest(auth, client, targetRepo, module, version)
-	if err != nil {
-		return false, err
-	}
-	if res.StatusCode == 200 {
-		return true, nil
-	}
-	return false, nil
-}
-
 func GetDependencies(cachePath string, moduleSlice map[string]bool) ([]Package, error) {
 	var deps []Package
 	for module := range moduleSlice {
 		moduleInfo := strings.Split(module, "@")
-		name := getDependencyName(moduleInfo[0])
-		dep, err := createDependency(cachePath, name, moduleInfo[1])
+		dep, err := createDependency(cachePath, moduleInfo[0], moduleInfo[1])
 		if err != nil {
 			return nil, err
 		}
@@ -249,27 +409,17 @@ func GetDependencies(cachePath string, moduleSlice map[string]bool) ([]Package,
 	return deps, nil
 }
 
-// Returns the actual path to the dependency.
-// If in the path there are capital letters, the Go convention is to use "!" before the letter.
-// The letter itself in lowercase.
-func getDependencyName(name string) string {
-	path := ""
-	for _, letter := range name {
-		if unicode.IsUpper(letter) {
-			path += "!" + strings.ToLower(string(letter))
-		} else {
-			path += string(letter)
-		}
-	}
-	return path
-}
-
 // Creates a go dependency.
 // Returns a nil value in case the dependency does not include a zip in the cache.
-func createDependency(cachePath, dependencyName, version string) (*Package, error) {
+func createDependency(cachePath, name, version string) (*Package, error) {
+	escapedName, escapedVersion, err := escapeModuleAndVersion(name, version)
+	if err != nil {
+		return nil, err
+	}
+
 	// We first check if the this dependency has a zip binary in the local go cache.
 	// If it does not, nil is returned. This seems to be a bug in go.
-	zipPath, err := getPackageZipLocation(cachePath, dependencyName, version)
+	zipPath, err := getPackageZipLocation(cachePath, escapedName, escapedVersion)
 
 	if err != nil {
 		return nil, err
@@ -281,22 +431,32 @@ func createDependency(cachePath, dependencyName, version string) (*Package, erro
 
 	dep := Package{}
 
-	dep.id = strings.Join([]string{dependencyName, version}, ":")
+	dep.id = strings.Join([]string{name, version}, ":")
 	dep.version = version
 	dep.zipPath = zipPath
-	dep.modPath = filepath.Join(cachePath, dependencyName, "@v", version+".mod")
+	dep.modPath = filepath.Join(cachePath, escapedName, "@v", escapedVersion+".mod")
 	dep.modContent, err = ioutil.ReadFile(dep.modPath)
 	if err != nil {
 		return &dep, errorutils.CheckError(err)
 	}
 
+	// dep.goModHash, in the go.sum h1: form, so the published build-info can be cross-checked
+	// against GOSUMDB records and "go mod verify" output. This is distinct from the Sha256
+	// checksum fields below, which are plain digests of the actual file bytes.
+	hashes, err := computeModuleHashes(name, version, dep.zipPath)
+	if err != nil {
+		return &dep, errorutils.CheckError(err)
+	}
+	dep.goModHash = hashes.goModHash
+	dep.zipHash = hashes.zipHash
+
 	// Mod file dependency for the build-info
 	modDependency := buildinfo.Dependency{Id: dep.id}
 	checksums, err := checksum.Calc(bytes.NewBuffer(dep.modContent))
 	if err != nil {
 		return &dep, err
 	}
-	modDependency.Checksum = &buildinfo.Checksum{Sha1: checksums[checksum.SHA1], Md5: checksums[checksum.MD5]}
+	modDependency.Checksum = &buildinfo.Checksum{Sha1: checksums[checksum.SHA1], Md5: checksums[checksum.MD5], Sha256: checksums[checksum.SHA256]}
 
 	// Zip file dependency for the build-info
 	zipDependency := buildinfo.Dependency{Id: dep.id}
@@ -304,7 +464,7 @@ func createDependency(cachePath, dependencyName, version string) (*Package, erro
 	if err != nil {
 		return &dep, err
 	}
-	zipDependency.Checksum = &buildinfo.Checksum{Sha1: fileDetails.Checksum.Sha1, Md5: fileDetails.Checksum.Md5}
+	zipDependency.Checksum = &buildinfo.Checksum{Sha1: fileDetails.Checksum.Sha1, Md5: fileDetails.Checksum.Md5, Sha256: fileDetails.Checksum.Sha256}
 
 	dep.buildInfoDependencies = append(dep.buildInfoDependencies, modDependency, zipDependency)
 	return &dep, nil
@@ -359,108 +519,139 @@ func getGOPATH() (string, error) {
 	return strings.TrimSpace(string(output)), nil
 }
 
-func mergeReplaceDependenciesWithGraphDependencies(replaceDeps []string, graphDeps map[string]bool) {
-	for _, replaceLine := range replaceDeps {
-		// Remove unnecessary spaces
-		replaceLine = strings.TrimSpace(replaceLine)
-		log.Debug("Working on the following replace line:", replaceLine)
-		// Split to get the right side that is the replace of the dependency
-		replaceDeps := strings.Split(replaceLine, "=>")
-		// Perform validation
-		if len(replaceDeps) < 2 {
-			log.Debug("The following replace line includes less then two elements", replaceDeps)
-			continue
-		}
-		replacesInfo := strings.TrimSpace(replaceDeps[1])
-		newDependency := strings.Split(replacesInfo, " ")
-		if len(newDependency) != 2 {
-			log.Debug("The replacer is not pointing to a VCS version", newDependency[0])
-			continue
-		}
-		// Check if the dependency in the map, if not add to the map
-		_, exists := graphDeps[newDependency[0]+"@"+newDependency[1]]
-		if !exists {
-			log.Debug("Adding dependency", newDependency[0], newDependency[1])
-			graphDeps[newDependency[0]+"@"+newDependency[1]] = true
-		}
+// Returns the path to the local Go module cache ($GOPATH/pkg/mod).
+func getModCachePath() (string, error) {
+	goPath, err := getGOPATH()
+	if err != nil {
+		return "", err
 	}
+	return filepath.Join(goPath, "pkg", "mod"), nil
 }
 
-func getReplaceDependencies() ([]string, error) {
-	replaceRegExp, err := cmd.GetRegExp(`\s*replace (?:[\(\w\.@:%_\+-.~#?&]?.+)`)
-	if err != nil {
-		return nil, err
+// LocalReplace is a go.mod "replace" directive pointing at a local filesystem path rather than
+// a versioned module, e.g. "replace example.com/a => ../a". It is not resolved through
+// Artifactory, so callers decide separately whether to treat it as a workspace dependency.
+type LocalReplace struct {
+	Old string
+	New string
+}
+
+// mergeReplaceDependenciesWithGraphDependencies adds every version-style go.mod replace target
+// to the dependency graph, so the replacement module is downloaded and published as well; the
+// entry of the module it replaces is left in the graph.
+func mergeReplaceDependenciesWithGraphDependencies(versionReplaces map[string]string, graphDeps map[string]bool) {
+	for oldPath, newModuleAndVersion := range versionReplaces {
+		if _, exists := graphDeps[newModuleAndVersion]; !exists {
+			log.Debug("Adding dependency", newModuleAndVersion, "replacing", oldPath)
+			graphDeps[newModuleAndVersion] = true
+		}
 	}
+}
+
+// getReplaceDependencies parses the project's go.mod structurally with
+// golang.org/x/mod/modfile, rather than hand-rolled regexes, so block-form "replace (...)"
+// stanzas, mid-line comments and local path replacements are all handled correctly.
+// Version-style replacements are returned as "old path" -> "new path@version", ready to merge
+// into the dependency graph; local path replacements (which have no version) are returned
+// separately, since they can't be resolved through Artifactory.
+func getReplaceDependencies() (versionReplaces map[string]string, localReplaces []LocalReplace, err error) {
 	rootDir, err := cmd.GetProjectRoot()
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	modFilePath := filepath.Join(rootDir, "go.mod")
 	modFileContent, err := ioutil.ReadFile(modFilePath)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+	parsedModFile, err := modfile.Parse(modFilePath, modFileContent, nil)
+	if err != nil {
+		return nil, nil, errorutils.CheckError(err)
+	}
+
+	versionReplaces = map[string]string{}
+	for _, replace := range parsedModFile.Replace {
+		if replace.New.Version == "" {
+			// A local path replacement ("replace a/b => ../local/dir") has no version on the
+			// right-hand side; surface it instead of silently dropping it.
+			localReplaces = append(localReplaces, LocalReplace{Old: replace.Old.Path, New: replace.New.Path})
+			continue
+		}
+		versionReplaces[replace.Old.Path] = replace.New.Path + "@" + replace.New.Version
 	}
-	replaceDependencies := replaceRegExp.FindAllString(string(modFileContent), -1)
-	return replaceDependencies, nil
+	return versionReplaces, localReplaces, nil
 }
 
-// Runs go mod graph command with fallback.
+// Runs go mod graph with fallback across the resolver chain (Artifactory, any configured
+// mirrors, then direct VCS), advancing to the next resolver whenever a module in the graph
+// turns out not to be available from the one that was just tried.
 func getDependenciesGraphWithFallback(targetRepo string, auth auth.ArtifactoryDetails) (map[string]bool, error) {
-	dependenciesMap := map[string]bool{}
-	modulesWithErrors := map[string]previousTries{}
-	usedProxy := true
-	for true {
-		// Configuring each run to use Artifactory/VCS
-		err := setOrUnsetGoProxy(usedProxy, targetRepo, auth)
-		if err != nil {
+	resolvers := buildResolverChain(targetRepo, auth)
+	modulesWithErrors := map[string]*previousTries{}
+	resolverIndex := 0
+	for {
+		resolver := resolvers[resolverIndex]
+		if err := resolver.Configure(); err != nil {
 			return nil, err
 		}
-		usedProxy = !usedProxy
-		dependenciesMap, err = cmd.GetDependenciesGraph()
+		log.Debug("Trying to resolve the dependency graph from", resolver.Name())
+		dependenciesMap, err := runGoModGraph()
 		if err == nil {
-			break
+			return dependenciesMap, nil
 		}
-		moduleAndVersion, err := getModuleAndVersion(usedProxy, err)
-		if err != nil {
-			return nil, err
+
+		moduleAndVersion, notFound, parseErr := getModuleAndVersion(resolver.Name(), err)
+		if parseErr != nil {
+			return nil, parseErr
+		}
+		if !notFound {
+			return nil, errorutils.CheckError(err)
 		}
+
 		modulePreviousTries, ok := modulesWithErrors[moduleAndVersion]
-		modulePreviousTries.setTriedFrom(usedProxy)
-		if ok && modulePreviousTries.triedFromVCS && modulePreviousTries.triedFromArtifactory {
-			return nil, errorutils.CheckError(errors.New(fmt.Sprintf(FailedToRetrieve+" %s "+FromBothArtifactoryAndVcs, moduleAndVersion)))
+		if !ok {
+			modulePreviousTries = &previousTries{}
+			modulesWithErrors[moduleAndVersion] = modulePreviousTries
+		}
+		modulePreviousTries.setTriedFrom(resolver.Name())
+		if modulePreviousTries.hasTriedAll(resolvers) {
+			return nil, errorutils.CheckError(errors.New(fmt.Sprintf("%s %s from any of: %s", FailedToRetrieve, moduleAndVersion, resolverNames(resolvers))))
 		}
-		modulesWithErrors[moduleAndVersion] = modulePreviousTries
+
+		resolverIndex = (resolverIndex + 1) % len(resolvers)
 	}
-	return dependenciesMap, nil
 }
 
-func setOrUnsetGoProxy(usedProxy bool, targetRepo string, auth auth.ArtifactoryDetails) error {
-	if !usedProxy {
-		log.Debug("Trying download the dependencies from Artifactory...")
-		return cmd.SetGoProxyEnvVar(auth.GetUrl(), auth.GetUser(), auth.GetPassword(), targetRepo)
-	} else {
-		log.Debug("Trying download the dependencies from the VCS...")
-		return errorutils.CheckError(os.Unsetenv(cmd.GOPROXY))
+func resolverNames(resolvers []Resolver) string {
+	names := make([]string, len(resolvers))
+	for i, resolver := range resolvers {
+		names[i] = resolver.Name()
 	}
+	return strings.Join(names, ", ")
 }
 
-func getModuleAndVersion(usedProxy bool, err error) (string, error) {
+// getModuleAndVersion extracts "module version" from a "go mod graph" error message, and
+// classifies whether the failure means the resolver that was just tried simply doesn't have
+// the module (so the chain should advance) or is a hard failure that should abort resolution.
+func getModuleAndVersion(resolverName string, err error) (moduleAndVersion string, notFound bool, parseErr error) {
 	splittedLine := strings.Split(err.Error(), ":")
-	logDebug(err, usedProxy)
+	logDebug(err, resolverName)
 	if len(splittedLine) < 2 {
-		return "", errorutils.CheckError(errors.New("Missing module name and version in the error message " + err.Error()))
+		return "", false, errorutils.CheckError(errors.New("Missing module name and version in the error message " + err.Error()))
 	}
-	return strings.TrimSpace(splittedLine[1]), nil
+	return strings.TrimSpace(splittedLine[1]), isNotFoundError(err), nil
 }
 
-func logDebug(err error, usedProxy bool) {
-	message := "Received " + err.Error() + " from"
-	if usedProxy {
-		message += " Artifactory."
-	} else {
-		message += " VCS."
-	}
-	log.Debug(message)
+// isNotFoundError reports whether a "go mod graph" error indicates the module simply isn't
+// available from the resolver that was just tried, as opposed to a hard failure (auth,
+// network, disk) that should abort resolution rather than advance the chain.
+func isNotFoundError(err error) bool {
+	message := strings.ToLower(err.Error())
+	return strings.Contains(message, "404") || strings.Contains(message, "not found") || strings.Contains(message, "no matching versions")
+}
+
+func logDebug(err error, resolverName string) {
+	log.Debug(fmt.Sprintf("Received %s from %s.", err.Error(), resolverName))
 }
 
 func populateModWithTidy(path string) error {
@@ -501,15 +692,25 @@ func runGoModGraph() (output map[string]bool, err error) {
 	return cmd.GetDependenciesGraph()
 }
 
+// previousTries tracks, per "module@version", which resolvers in the chain have already been
+// attempted, so getDependenciesGraphWithFallback can tell a real miss (every resolver tried
+// and failed) from one that just needs to advance to the next resolver.
 type previousTries struct {
-	triedFromArtifactory bool
-	triedFromVCS         bool
+	tried map[string]bool
 }
 
-func (pt *previousTries) setTriedFrom(usedProxy bool) {
-	if usedProxy {
-		pt.triedFromArtifactory = true
-	} else {
-		pt.triedFromVCS = true
+func (pt *previousTries) setTriedFrom(resolverName string) {
+	if pt.tried == nil {
+		pt.tried = map[string]bool{}
+	}
+	pt.tried[resolverName] = true
+}
+
+func (pt *previousTries) hasTriedAll(resolvers []Resolver) bool {
+	for _, resolver := range resolvers {
+		if !pt.tried[resolver.Name()] {
+			return false
+		}
 	}
+	return true
 }
\ No newline at end of file
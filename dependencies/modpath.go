@@ -0,0 +1,22 @@
+package dependencies
+
+import (
+	"github.com/jfrog/jfrog-client-go/utils/errorutils"
+	"golang.org/x/mod/module"
+)
+
+// escapeModuleAndVersion encodes a module path and version the same way the go command does
+// before turning them into a proxy URL or a module cache path (golang.org/x/mod/module's
+// "safe encoding", golang.org/issue/25992): every uppercase letter is escaped as "!"+lower,
+// so that the result is safe on case-insensitive filesystems and unambiguous as a URL segment.
+func escapeModuleAndVersion(name, version string) (escapedName, escapedVersion string, err error) {
+	escapedName, err = module.EscapePath(name)
+	if err != nil {
+		return "", "", errorutils.CheckError(err)
+	}
+	escapedVersion, err = module.EscapeVersion(version)
+	if err != nil {
+		return "", "", errorutils.CheckError(err)
+	}
+	return escapedName, escapedVersion, nil
+}
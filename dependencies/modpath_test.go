@@ -0,0 +1,57 @@
+package dependencies
+
+import "testing"
+
+func TestEscapeModuleAndVersion(t *testing.T) {
+	tests := []struct {
+		name        string
+		module      string
+		version     string
+		wantName    string
+		wantVersion string
+	}{
+		{
+			name:        "lowercase module and semver version",
+			module:      "github.com/jfrog/gocmd",
+			version:     "v1.2.3",
+			wantName:    "github.com/jfrog/gocmd",
+			wantVersion: "v1.2.3",
+		},
+		{
+			name:        "uppercase path elements",
+			module:      "github.com/BurntSushi/toml",
+			version:     "v0.3.1",
+			wantName:    "github.com/!burnt!sushi/toml",
+			wantVersion: "v0.3.1",
+		},
+		{
+			name:        "+incompatible version",
+			module:      "github.com/Shopify/sarama",
+			version:     "v1.19.0+incompatible",
+			wantName:    "github.com/!shopify/sarama",
+			wantVersion: "v1.19.0+incompatible",
+		},
+		{
+			name:        "pseudo-version",
+			module:      "golang.org/x/mod",
+			version:     "v0.0.0-20200828183125-ce943fd02449",
+			wantName:    "golang.org/x/mod",
+			wantVersion: "v0.0.0-20200828183125-ce943fd02449",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotName, gotVersion, err := escapeModuleAndVersion(tt.module, tt.version)
+			if err != nil {
+				t.Fatalf("escapeModuleAndVersion(%q, %q) returned error: %v", tt.module, tt.version, err)
+			}
+			if gotName != tt.wantName {
+				t.Errorf("escapeModuleAndVersion(%q, %q) name = %q, want %q", tt.module, tt.version, gotName, tt.wantName)
+			}
+			if gotVersion != tt.wantVersion {
+				t.Errorf("escapeModuleAndVersion(%q, %q) version = %q, want %q", tt.module, tt.version, gotVersion, tt.wantVersion)
+			}
+		})
+	}
+}
@@ -0,0 +1,201 @@
+package dependencies
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/jfrog/gocmd/utils/cmd"
+	"github.com/jfrog/jfrog-client-go/artifactory/auth"
+	"github.com/jfrog/jfrog-client-go/httpclient"
+	"github.com/jfrog/jfrog-client-go/utils/errorutils"
+	"github.com/jfrog/jfrog-client-go/utils/log"
+	"github.com/pkg/errors"
+)
+
+// extraProxiesEnvVar chains additional GOPROXY-compatible mirrors onto the resolver chain,
+// after Artifactory and before direct VCS access - the common case of migrating off
+// Artifactory onto a public mirror such as proxy.golang.org.
+const extraProxiesEnvVar = "GOCMD_GO_PROXY_URLS"
+
+// Resolver is a single source Go modules can be resolved from. getDependenciesGraphWithFallback
+// and downloadSingleDependency walk an ordered []Resolver exactly like the go command walks its
+// own comma-separated GOPROXY=p1,p2,direct,off list, advancing to the next resolver whenever
+// the current one reports that it doesn't have the module.
+type Resolver interface {
+	// Name identifies the resolver for logging and previousTries bookkeeping.
+	Name() string
+	// Configure points the go command at this resolver (by setting or unsetting GOPROXY), so a
+	// subsequent "go mod graph" / "go mod download" is served from it.
+	Configure() error
+	// Head reports whether module@version is available from this resolver, without downloading it.
+	Head(client *httpclient.HttpClient, module, version string) (found bool, err error)
+	// Download fetches module@version from this resolver. Configure must have been called first.
+	Download(fullDependencyName string) error
+}
+
+// ArtifactoryProxy resolves modules through an Artifactory Go repository.
+type ArtifactoryProxy struct {
+	TargetRepo string
+	Auth       auth.ArtifactoryDetails
+}
+
+func (r *ArtifactoryProxy) Name() string { return "artifactory:" + r.TargetRepo }
+
+func (r *ArtifactoryProxy) Configure() error {
+	return errorutils.CheckError(cmd.SetGoProxyEnvVar(r.Auth.GetUrl(), r.Auth.GetUser(), r.Auth.GetPassword(), r.TargetRepo))
+}
+
+func (r *ArtifactoryProxy) Head(client *httpclient.HttpClient, module, version string) (bool, error) {
+	resp, err := performHeadRequest(r.Auth, client, r.TargetRepo, module, version)
+	if err != nil {
+		return false, err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return false, errorutils.CheckError(errors.New(fmt.Sprintf("received unexpected status %d probing %s@%s on %s", resp.StatusCode, module, version, r.Name())))
+	}
+	return true, nil
+}
+
+func (r *ArtifactoryProxy) Download(fullDependencyName string) error {
+	log.Debug("Downloading dependency from Artifactory:", fullDependencyName)
+	return cmd.DownloadDependency(fullDependencyName)
+}
+
+// GenericGoProxy resolves modules through any GOPROXY-compatible HTTP endpoint, such as a
+// public mirror like proxy.golang.org.
+type GenericGoProxy struct {
+	ProxyUrl string
+}
+
+func (r *GenericGoProxy) Name() string { return "proxy:" + r.ProxyUrl }
+
+func (r *GenericGoProxy) Configure() error {
+	return errorutils.CheckError(os.Setenv(cmd.GOPROXY, r.ProxyUrl))
+}
+
+func (r *GenericGoProxy) Head(client *httpclient.HttpClient, module, version string) (bool, error) {
+	escapedName, escapedVersion, err := escapeModuleAndVersion(module, version)
+	if err != nil {
+		return false, err
+	}
+	url := strings.TrimSuffix(r.ProxyUrl, "/") + "/" + escapedName + "/@v/" + escapedVersion + ".info"
+	resp, _, err := client.SendHead(url, httpclient.HttpClientDetails{})
+	if err != nil {
+		return false, err
+	}
+	return resp.StatusCode == http.StatusOK, nil
+}
+
+func (r *GenericGoProxy) Download(fullDependencyName string) error {
+	log.Debug("Downloading dependency from proxy", r.ProxyUrl, ":", fullDependencyName)
+	return cmd.DownloadDependency(fullDependencyName)
+}
+
+// Direct resolves modules straight from their VCS, bypassing any proxy.
+type Direct struct{}
+
+func (r *Direct) Name() string { return "direct" }
+
+func (r *Direct) Configure() error {
+	return errorutils.CheckError(os.Unsetenv(cmd.GOPROXY))
+}
+
+func (r *Direct) Head(client *httpclient.HttpClient, module, version string) (bool, error) {
+	// Direct VCS access has no cheap HEAD probe; report it reachable and let Download surface a
+	// hard failure if it turns out not to be.
+	return true, nil
+}
+
+func (r *Direct) Download(fullDependencyName string) error {
+	log.Debug("Downloading dependency from VCS:", fullDependencyName)
+	return cmd.DownloadDependency(fullDependencyName)
+}
+
+// Off terminates a resolution chain without resolving or downloading anything, matching the
+// "off" keyword the go command itself accepts in GOPROXY.
+type Off struct{}
+
+func (r *Off) Name() string { return "off" }
+
+func (r *Off) Configure() error {
+	return errorutils.CheckError(os.Setenv(cmd.GOPROXY, "off"))
+}
+
+func (r *Off) Head(client *httpclient.HttpClient, module, version string) (bool, error) {
+	return false, nil
+}
+
+func (r *Off) Download(fullDependencyName string) error {
+	return errorutils.CheckError(errors.New("module resolution is disabled (GOPROXY=off): " + fullDependencyName))
+}
+
+// buildResolverChain builds the ordered resolver list modules are resolved against: the
+// Artifactory repository first, then any mirrors configured via extraProxiesEnvVar, then direct
+// VCS access - analogous to the go command's own comma-separated GOPROXY=p1,p2,direct,off list.
+// As in GOPROXY, the literal tokens "direct" and "off" in extraProxiesEnvVar are recognized as
+// Direct/Off resolvers rather than proxy URLs, and terminate the chain: entries after them are
+// ignored, and the implicit trailing Direct is only appended when the chain wasn't already
+// terminated explicitly.
+func buildResolverChain(targetRepo string, auth auth.ArtifactoryDetails) []Resolver {
+	resolvers := []Resolver{&ArtifactoryProxy{TargetRepo: targetRepo, Auth: auth}}
+	terminated := false
+	if extraProxies := os.Getenv(extraProxiesEnvVar); extraProxies != "" {
+		for _, token := range strings.Split(extraProxies, ",") {
+			if token = strings.TrimSpace(token); token == "" {
+				continue
+			}
+			switch token {
+			case "off":
+				resolvers = append(resolvers, &Off{})
+				terminated = true
+			case "direct":
+				resolvers = append(resolvers, &Direct{})
+				terminated = true
+			default:
+				resolvers = append(resolvers, &GenericGoProxy{ProxyUrl: token})
+			}
+			if terminated {
+				break
+			}
+		}
+	}
+	if !terminated {
+		resolvers = append(resolvers, &Direct{})
+	}
+	return resolvers
+}
+
+// resolveModule walks resolvers in order, probing each until one reports the module is
+// available. If none do, it returns the last resolver in the chain so its Download call can
+// produce a meaningful, resolver-specific error. It deliberately does not call Configure: none
+// of the Head implementations depend on the process-wide GOPROXY state, and probing runs
+// concurrently across workers, while Configure must run immediately before the matching
+// Download, under downloadInvocationMutex, to avoid one worker's GOPROXY clobbering another's.
+func resolveModule(resolvers []Resolver, client *httpclient.HttpClient, name, version string) (Resolver, bool, error) {
+	for _, resolver := range resolvers {
+		found, err := resolver.Head(client, name, version)
+		if err != nil {
+			return nil, false, err
+		}
+		if found {
+			return resolver, true, nil
+		}
+		log.Debug(fmt.Sprintf("%s does not have %s@%s, advancing to the next resolver.", resolver.Name(), name, version))
+	}
+	return resolvers[len(resolvers)-1], false, nil
+}
+
+// artifactoryDetailsOf returns the target repo and credentials backing resolver if it is an
+// *ArtifactoryProxy, so sumdb verification can fall back to Artifactory's GOSUMDB mirror; for
+// any other resolver it returns zero values, which verifyModule treats as "no mirror available".
+func artifactoryDetailsOf(resolver Resolver) (string, auth.ArtifactoryDetails) {
+	if proxy, ok := resolver.(*ArtifactoryProxy); ok {
+		return proxy.TargetRepo, proxy.Auth
+	}
+	return "", nil
+}
@@ -0,0 +1,78 @@
+package dependencies
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jfrog/jfrog-client-go/httpclient"
+	"golang.org/x/sync/errgroup"
+)
+
+// latencyResolver simulates a resolver whose Head probe is network-bound, without depending on
+// the real utils/cache and utils/cmd packages a real download would need.
+type latencyResolver struct{ latency time.Duration }
+
+func (r *latencyResolver) Name() string { return "bench" }
+
+func (r *latencyResolver) Configure() error { return nil }
+
+func (r *latencyResolver) Head(client *httpclient.HttpClient, module, version string) (bool, error) {
+	time.Sleep(r.latency)
+	return true, nil
+}
+
+func (r *latencyResolver) Download(fullDependencyName string) error { return nil }
+
+func benchModules(count int) []string {
+	modules := make([]string, count)
+	for i := range modules {
+		modules[i] = fmt.Sprintf("example.com/module%d@v1.0.%d", i, i)
+	}
+	return modules
+}
+
+// BenchmarkResolveModules compares resolving ~500 modules' HEAD probes serially against resolving
+// them through the same bounded worker pool downloadDependencies uses, demonstrating the
+// speedup chunk0-3 set out to deliver for the network-bound probe step. It intentionally does
+// not exercise resolver.Download, since downloadInvocationMutex still serializes "go mod
+// download" invocations across workers - overlapping those too would require passing GOPROXY
+// through cmd.DownloadDependency's exec.Cmd.Env instead of the process environment, a change to
+// utils/cmd that is out of scope here.
+func BenchmarkResolveModules(b *testing.B) {
+	const moduleCount = 500
+	resolvers := []Resolver{&latencyResolver{latency: time.Millisecond}}
+	modules := benchModules(moduleCount)
+
+	b.Run("serial", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for _, module := range modules {
+				nameAndVersion := strings.Split(module, "@")
+				if _, _, err := resolveModule(resolvers, nil, nameAndVersion[0], nameAndVersion[1]); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+
+	b.Run("parallel", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			var errGroup errgroup.Group
+			semaphore := make(chan struct{}, getDownloadParallelism())
+			for _, module := range modules {
+				module := module
+				semaphore <- struct{}{}
+				errGroup.Go(func() error {
+					defer func() { <-semaphore }()
+					nameAndVersion := strings.Split(module, "@")
+					_, _, err := resolveModule(resolvers, nil, nameAndVersion[0], nameAndVersion[1])
+					return err
+				})
+			}
+			if err := errGroup.Wait(); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,196 @@
+package dependencies
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/jfrog/jfrog-client-go/artifactory/auth"
+	"github.com/jfrog/jfrog-client-go/httpclient"
+	"github.com/jfrog/jfrog-client-go/utils/errorutils"
+	"github.com/jfrog/jfrog-client-go/utils/log"
+	"github.com/pkg/errors"
+	"golang.org/x/mod/sumdb/dirhash"
+)
+
+// VerifyMode controls how strictly downloaded modules are checked against go.sum / GOSUMDB
+// while being resolved through Artifactory.
+type VerifyMode int
+
+const (
+	// VerifyOff skips hash verification entirely. This is the historical behavior.
+	VerifyOff VerifyMode = iota
+	// VerifyWarn computes and compares hashes, logging a warning on a mismatch or a missing record.
+	VerifyWarn
+	// VerifyEnforce fails the build on any hash mismatch or missing record.
+	VerifyEnforce
+)
+
+// defaultGosumdb is used when the GOSUMDB environment variable is not set, mirroring the Go command's own default.
+const defaultGosumdb = "sum.golang.org"
+
+// sumdbLookupPathFormat mirrors Artifactory's GOSUMDB proxy endpoint: api/go/<repo>/sumdb/<GOSUMDB>/lookup/<module>@<version>
+const sumdbLookupPathFormat = "api/go/%s/sumdb/%s/lookup/%s@%s"
+
+// moduleHashes holds the h1: hashes of a single module, in the same form go.sum stores them.
+type moduleHashes struct {
+	zipHash   string
+	goModHash string
+}
+
+// verifyModule computes the h1: hashes of a downloaded module's zip and go.mod file, and
+// checks them against, in order of precedence, a user-supplied pin file, the project's
+// go.sum and an Artifactory-hosted GOSUMDB mirror. pinHashes and goSumHashes are both parsed
+// once by the caller and passed in, rather than reread per call. On a mismatch, in VerifyEnforce
+// mode it returns an error identifying the module and both the expected and actual hashes; in
+// VerifyWarn mode it logs that message as a warning and returns nil.
+func verifyModule(mode VerifyMode, name, version, cachePath string, goSumHashes, pinHashes map[string]moduleHashes, targetRepo string, auth auth.ArtifactoryDetails, client *httpclient.HttpClient) error {
+	escapedName, escapedVersion, err := escapeModuleAndVersion(name, version)
+	if err != nil {
+		return err
+	}
+	zipPath, err := getPackageZipLocation(cachePath, escapedName, escapedVersion)
+	if err != nil {
+		return err
+	}
+	if zipPath == "" {
+		log.Debug(fmt.Sprintf("Skipping checksum verification for %s@%s: zip not found in cache.", name, version))
+		return nil
+	}
+
+	actual, err := computeModuleHashes(name, version, zipPath)
+	if err != nil {
+		return errorutils.CheckError(err)
+	}
+
+	expected, found, err := resolveExpectedHashes(name, version, goSumHashes, pinHashes, targetRepo, auth, client)
+	if err != nil {
+		return failVerification(mode, fmt.Sprintf("Failed resolving the expected checksum of %s@%s: %s", name, version, err.Error()))
+	}
+	if !found {
+		return failVerification(mode, fmt.Sprintf("No checksum record was found for %s@%s in go.sum, the pin file or %s", name, version, gosumdbName()))
+	}
+
+	if expected.zipHash != actual.zipHash {
+		return failVerification(mode, fmt.Sprintf("Checksum mismatch for %s@%s: expected zip hash %s, got %s", name, version, expected.zipHash, actual.zipHash))
+	}
+	if expected.goModHash != "" && expected.goModHash != actual.goModHash {
+		return failVerification(mode, fmt.Sprintf("Checksum mismatch for %s@%s: expected go.mod hash %s, got %s", name, version, expected.goModHash, actual.goModHash))
+	}
+	return nil
+}
+
+func failVerification(mode VerifyMode, message string) error {
+	if mode == VerifyWarn {
+		log.Warn(message)
+		return nil
+	}
+	return errorutils.CheckError(errors.New(message))
+}
+
+func gosumdbName() string {
+	if gosumdb := os.Getenv("GOSUMDB"); gosumdb != "" {
+		return gosumdb
+	}
+	return defaultGosumdb
+}
+
+// computeModuleHashes computes the h1: hash of the module zip and of its go.mod file, in the
+// same way golang.org/x/mod/sumdb/dirhash computes them when the go command writes go.sum.
+func computeModuleHashes(name, version, zipPath string) (moduleHashes, error) {
+	zipHash, err := dirhash.HashZip(zipPath, dirhash.Hash1)
+	if err != nil {
+		return moduleHashes{}, err
+	}
+	modPath := zipPath[:len(zipPath)-len(".zip")] + ".mod"
+	modContent, err := ioutil.ReadFile(modPath)
+	if err != nil {
+		return moduleHashes{}, err
+	}
+	goModHash, err := dirhash.Hash1([]string{"go.mod"}, func(string) (io.ReadCloser, error) {
+		return ioutil.NopCloser(bytes.NewReader(modContent)), nil
+	})
+	if err != nil {
+		return moduleHashes{}, err
+	}
+	return moduleHashes{zipHash: zipHash, goModHash: goModHash}, nil
+}
+
+// resolveExpectedHashes looks up the expected hashes of a module, preferring a user-supplied
+// pin file, then the project's go.sum, and finally an Artifactory-hosted GOSUMDB mirror.
+// pinHashes and goSumHashes are parsed once upfront by the caller, rather than being reread from
+// disk per module, since verifyModule runs once per module across the download worker pool.
+func resolveExpectedHashes(name, version string, goSumHashes, pinHashes map[string]moduleHashes, targetRepo string, auth auth.ArtifactoryDetails, client *httpclient.HttpClient) (moduleHashes, bool, error) {
+	key := name + "@" + version
+	if hashes, ok := pinHashes[key]; ok {
+		return hashes, true, nil
+	}
+	if hashes, ok := goSumHashes[key]; ok {
+		return hashes, true, nil
+	}
+	if auth == nil {
+		return moduleHashes{}, false, nil
+	}
+	return lookupSumdbMirror(name, version, targetRepo, auth, client)
+}
+
+// lookupSumdbMirror queries an Artifactory-hosted GOSUMDB mirror for a module's hashes.
+func lookupSumdbMirror(name, version, targetRepo string, auth auth.ArtifactoryDetails, client *httpclient.HttpClient) (moduleHashes, bool, error) {
+	escapedName, escapedVersion, err := escapeModuleAndVersion(name, version)
+	if err != nil {
+		return moduleHashes{}, false, err
+	}
+	url := auth.GetUrl() + fmt.Sprintf(sumdbLookupPathFormat, targetRepo, gosumdbName(), escapedName, escapedVersion)
+	resp, body, _, err := client.SendGet(url, true, auth.CreateHttpClientDetails())
+	if err != nil {
+		return moduleHashes{}, false, err
+	}
+	if resp.StatusCode == 404 {
+		return moduleHashes{}, false, nil
+	}
+	if resp.StatusCode != 200 {
+		return moduleHashes{}, false, errorutils.CheckError(errors.New(fmt.Sprintf("Received status %d from the GOSUMDB mirror at %s", resp.StatusCode, url)))
+	}
+
+	hashes := parseSumLinesFromContent(body)
+	moduleAndVersion, ok := hashes[name+"@"+version]
+	return moduleAndVersion, ok, nil
+}
+
+// parseSumLines reads a go.sum-formatted file (the project's go.sum or a user-supplied pin
+// file) into a map keyed by "module@version".
+func parseSumLines(path string) (map[string]moduleHashes, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return parseSumLinesFromContent(content), nil
+}
+
+// parseSumLinesFromContent parses the in-memory content of a go.sum-formatted file into a map
+// keyed by "module@version".
+func parseSumLinesFromContent(content []byte) map[string]moduleHashes {
+	result := map[string]moduleHashes{}
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 3 {
+			continue
+		}
+		module, version, hash := fields[0], fields[1], fields[2]
+		isGoMod := strings.HasSuffix(version, "/go.mod")
+		key := module + "@" + strings.TrimSuffix(version, "/go.mod")
+		entry := result[key]
+		if isGoMod {
+			entry.goModHash = hash
+		} else {
+			entry.zipHash = hash
+		}
+		result[key] = entry
+	}
+	return result
+}